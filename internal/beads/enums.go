@@ -0,0 +1,123 @@
+package beads
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+)
+
+// RoleType identifies the kind of agent role an agent bead represents.
+type RoleType string
+
+const (
+	RoleTypeMayor    RoleType = "mayor"
+	RoleTypeDeacon   RoleType = "deacon"
+	RoleTypeWitness  RoleType = "witness"
+	RoleTypeRefinery RoleType = "refinery"
+	RoleTypePolecat  RoleType = "polecat"
+)
+
+// IsValid reports whether r is one of the known role types. Matching is
+// case-sensitive: role types are always written lowercase.
+func (r RoleType) IsValid() bool {
+	switch r {
+	case RoleTypeMayor, RoleTypeDeacon, RoleTypeWitness, RoleTypeRefinery, RoleTypePolecat:
+		return true
+	}
+	return false
+}
+
+// validateRoleType reports an error if value is not a known RoleType.
+func validateRoleType(value string) error {
+	if !RoleType(value).IsValid() {
+		return fmt.Errorf("must be one of mayor, deacon, witness, refinery, polecat, got %q", value)
+	}
+	return nil
+}
+
+// AgentState is the run state of an agent bead.
+type AgentState string
+
+const (
+	AgentStateIdle    AgentState = "idle"
+	AgentStateRunning AgentState = "running"
+	AgentStateWorking AgentState = "working"
+	AgentStateStopped AgentState = "stopped"
+)
+
+// IsValid reports whether s is one of the known agent states. Matching is
+// case-sensitive: agent states are always written lowercase.
+func (s AgentState) IsValid() bool {
+	switch s {
+	case AgentStateIdle, AgentStateRunning, AgentStateWorking, AgentStateStopped:
+		return true
+	}
+	return false
+}
+
+// validateAgentState reports an error if value is not a known AgentState.
+func validateAgentState(value string) error {
+	if !AgentState(value).IsValid() {
+		return fmt.Errorf("must be one of idle, running, working, stopped, got %q", value)
+	}
+	return nil
+}
+
+// CloseReason is why a merge-request issue was closed.
+type CloseReason string
+
+const (
+	CloseReasonMerged     CloseReason = "merged"
+	CloseReasonRejected   CloseReason = "rejected"
+	CloseReasonConflict   CloseReason = "conflict"
+	CloseReasonSuperseded CloseReason = "superseded"
+)
+
+// IsValid reports whether r is one of the known close reasons. Matching is
+// case-sensitive: close reasons are always written lowercase.
+func (r CloseReason) IsValid() bool {
+	switch r {
+	case CloseReasonMerged, CloseReasonRejected, CloseReasonConflict, CloseReasonSuperseded:
+		return true
+	}
+	return false
+}
+
+// validateCloseReason reports an error if value is not a known CloseReason.
+func validateCloseReason(value string) error {
+	if !CloseReason(value).IsValid() {
+		return fmt.Errorf("must be one of merged, rejected, conflict, superseded, got %q", value)
+	}
+	return nil
+}
+
+// beadIDPattern matches bead IDs like "gt-xyz", "hq-wisp-abc" and scaffold
+// IDs like "mol-deacon-patrol.inbox-check".
+var beadIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]*$`)
+
+// shaPattern matches the abbreviated or full hex SHA of a merge commit.
+var shaPattern = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+
+// validateBeadID reports an error if value does not look like a bead ID.
+func validateBeadID(value string) error {
+	if !beadIDPattern.MatchString(value) {
+		return fmt.Errorf("not a valid bead ID: %q", value)
+	}
+	return nil
+}
+
+// validateSHA reports an error if value is not a 7-40 character hex SHA.
+func validateSHA(value string) error {
+	if !shaPattern.MatchString(value) {
+		return fmt.Errorf("not a valid commit SHA: %q", value)
+	}
+	return nil
+}
+
+// validateRFC3339 reports an error if value is not an RFC 3339 timestamp.
+func validateRFC3339(value string) error {
+	if _, err := time.Parse(time.RFC3339, value); err != nil {
+		return fmt.Errorf("not an RFC 3339 timestamp: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+package beads
+
+import "testing"
+
+func TestRoleTypeIsValid(t *testing.T) {
+	if !RoleType("deacon").IsValid() {
+		t.Error("deacon should be a valid role type")
+	}
+	if RoleType("Deacon").IsValid() {
+		t.Error("role type matching should be case-sensitive")
+	}
+	if RoleType("sheriff").IsValid() {
+		t.Error("sheriff is not a known role type")
+	}
+}
+
+func TestAgentStateIsValid(t *testing.T) {
+	if !AgentState("working").IsValid() {
+		t.Error("working should be a valid agent state")
+	}
+	if AgentState("paused").IsValid() {
+		t.Error("paused is not a known agent state")
+	}
+}
+
+func TestCloseReasonIsValid(t *testing.T) {
+	if !CloseReason("superseded").IsValid() {
+		t.Error("superseded should be a valid close reason")
+	}
+	if CloseReason("abandoned").IsValid() {
+		t.Error("abandoned is not a known close reason")
+	}
+}
+
+func TestValidateBeadID(t *testing.T) {
+	if err := validateBeadID("hq-wisp-abc"); err != nil {
+		t.Errorf("expected hq-wisp-abc to be valid, got %v", err)
+	}
+	if err := validateBeadID("mol-deacon-patrol.inbox-check"); err != nil {
+		t.Errorf("expected scaffold-style ID to be valid, got %v", err)
+	}
+	if err := validateBeadID("@@bad"); err == nil {
+		t.Error("expected @@bad to be rejected")
+	}
+}
+
+func TestValidateSHA(t *testing.T) {
+	if err := validateSHA("abc1234"); err != nil {
+		t.Errorf("expected 7-char hex SHA to be valid, got %v", err)
+	}
+	if err := validateSHA("zzzzzzz"); err == nil {
+		t.Error("expected non-hex SHA to be rejected")
+	}
+	if err := validateSHA("abc12"); err == nil {
+		t.Error("expected too-short SHA to be rejected")
+	}
+}
+
+func TestValidateRFC3339(t *testing.T) {
+	if err := validateRFC3339("2026-07-25T00:00:00Z"); err != nil {
+		t.Errorf("expected valid timestamp, got %v", err)
+	}
+	if err := validateRFC3339("2026-07-25"); err == nil {
+		t.Error("expected date-only value to be rejected")
+	}
+}
+
+func TestParseAgentFieldsStrictReportsIssuesWithLineNumbers(t *testing.T) {
+	issue := &Issue{Description: "rig: gastown\nrole_type: sheriff\nhook_bead: @@bad\nagent_state: idle\n"}
+
+	fields, issues, err := ParseAgentFieldsStrict(issue)
+	if fields == nil || fields.AgentState != "idle" || fields.Rig != "gastown" {
+		t.Fatalf("got fields %+v", fields)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d: %+v", len(issues), issues)
+	}
+	if issues[0].Key != "role_type" || issues[0].Line != 2 {
+		t.Errorf("unexpected first issue: %+v", issues[0])
+	}
+	if issues[1].Key != "hook_bead" || issues[1].Line != 3 {
+		t.Errorf("unexpected second issue: %+v", issues[1])
+	}
+	if err == nil {
+		t.Error("expected a non-nil summary error")
+	}
+}
+
+func TestParseAgentFieldsStrictNoIssuesForValidFields(t *testing.T) {
+	issue := &Issue{Description: "role_type: mayor\nagent_state: running\n"}
+
+	fields, issues, err := ParseAgentFieldsStrict(issue)
+	if fields == nil || fields.RoleType != "mayor" {
+		t.Fatalf("got fields %+v", fields)
+	}
+	if len(issues) != 0 {
+		t.Errorf("expected no issues, got %+v", issues)
+	}
+	if err != nil {
+		t.Errorf("expected nil error, got %v", err)
+	}
+}
+
+func TestParseAttachmentFieldsStrictReportsIssues(t *testing.T) {
+	issue := &Issue{Description: "attached_molecule: @@bad\nattached_at: 2026-07-25\nattached_args: retry\n"}
+
+	fields, issues, err := ParseAttachmentFieldsStrict(issue)
+	if fields == nil || fields.AttachedArgs != "retry" {
+		t.Fatalf("got fields %+v", fields)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d: %+v", len(issues), issues)
+	}
+	if issues[0].Key != "attached_molecule" || issues[0].Line != 1 {
+		t.Errorf("unexpected first issue: %+v", issues[0])
+	}
+	if issues[1].Key != "attached_at" || issues[1].Line != 2 {
+		t.Errorf("unexpected second issue: %+v", issues[1])
+	}
+	if err == nil {
+		t.Error("expected a non-nil summary error")
+	}
+}
+
+func TestParseMRFieldsStrictReportsIssues(t *testing.T) {
+	issue := &Issue{Description: "source_issue: gt-123\nmerge_commit: not-a-sha\nclose_reason: abandoned\n"}
+
+	fields, issues, err := ParseMRFieldsStrict(issue)
+	if fields == nil || fields.SourceIssue != "gt-123" {
+		t.Fatalf("got fields %+v", fields)
+	}
+	if len(issues) != 2 {
+		t.Fatalf("expected 2 issues, got %d: %+v", len(issues), issues)
+	}
+	if err == nil {
+		t.Error("expected a non-nil summary error")
+	}
+}
@@ -1,7 +1,17 @@
 // Package beads provides field parsing utilities for structured issue descriptions.
 package beads
 
-import "strings"
+// AgentFieldSchema declares the fields stored in an agent bead's description.
+var AgentFieldSchema = &FieldSchema{
+	Name: "agent",
+	Fields: []FieldDef{
+		{Key: "role_type", Aliases: []string{"role-type", "roletype"}, Validate: validateRoleType},
+		{Key: "rig"},
+		{Key: "agent_state", Aliases: []string{"agent-state", "agentstate"}, Validate: validateAgentState},
+		{Key: "hook_bead", Aliases: []string{"hook-bead", "hookbead"}, Validate: validateBeadID},
+		{Key: "role_bead", Aliases: []string{"role-bead", "rolebead"}, Validate: validateBeadID},
+	},
+}
 
 // AgentFields holds parsed fields from an agent bead's description.
 // Agent beads store their state as key: value lines in the description.
@@ -25,53 +35,17 @@ func ParseAgentFields(issue *Issue) *AgentFields {
 // ParseAgentFieldsFromDescription extracts agent fields from a description string.
 // Returns nil if no agent fields found.
 func ParseAgentFieldsFromDescription(description string) *AgentFields {
-	if description == "" {
+	values := ParseFieldsFromDescription(description, AgentFieldSchema)
+	if values == nil {
 		return nil
 	}
-
-	fields := &AgentFields{}
-	hasFields := false
-
-	for _, line := range strings.Split(description, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		colonIdx := strings.Index(line, ":")
-		if colonIdx == -1 {
-			continue
-		}
-
-		key := strings.TrimSpace(line[:colonIdx])
-		value := strings.TrimSpace(line[colonIdx+1:])
-		if value == "" || value == "null" {
-			continue
-		}
-
-		switch strings.ToLower(key) {
-		case "role_type", "role-type", "roletype":
-			fields.RoleType = value
-			hasFields = true
-		case "rig":
-			fields.Rig = value
-			hasFields = true
-		case "agent_state", "agent-state", "agentstate":
-			fields.AgentState = value
-			hasFields = true
-		case "hook_bead", "hook-bead", "hookbead":
-			fields.HookBead = value
-			hasFields = true
-		case "role_bead", "role-bead", "rolebead":
-			fields.RoleBead = value
-			hasFields = true
-		}
+	return &AgentFields{
+		RoleType:   values["role_type"],
+		Rig:        values["rig"],
+		AgentState: values["agent_state"],
+		HookBead:   values["hook_bead"],
+		RoleBead:   values["role_bead"],
 	}
-
-	if !hasFields {
-		return nil
-	}
-	return fields
 }
 
 // FormatAgentFields formats AgentFields as a string suitable for an issue description.
@@ -80,26 +54,42 @@ func FormatAgentFields(fields *AgentFields) string {
 	if fields == nil {
 		return ""
 	}
+	return FormatFields(AgentFieldSchema, map[string]string{
+		"role_type":   fields.RoleType,
+		"rig":         fields.Rig,
+		"agent_state": fields.AgentState,
+		"hook_bead":   fields.HookBead,
+		"role_bead":   fields.RoleBead,
+	})
+}
 
-	var lines []string
-
-	if fields.RoleType != "" {
-		lines = append(lines, "role_type: "+fields.RoleType)
-	}
-	if fields.Rig != "" {
-		lines = append(lines, "rig: "+fields.Rig)
-	}
-	if fields.AgentState != "" {
-		lines = append(lines, "agent_state: "+fields.AgentState)
-	}
-	if fields.HookBead != "" {
-		lines = append(lines, "hook_bead: "+fields.HookBead)
-	}
-	if fields.RoleBead != "" {
-		lines = append(lines, "role_bead: "+fields.RoleBead)
+// ParseAgentFieldsStrict is like ParseAgentFields but validates role_type and
+// agent_state against their known enums, and hook_bead/role_bead against the
+// bead-ID pattern, returning one FieldIssue per invalid value. The returned
+// error is a summary of issues for callers that only need a pass/fail check.
+func ParseAgentFieldsStrict(issue *Issue) (*AgentFields, []FieldIssue, error) {
+	values, issues := ParseFieldsStrict(issue, AgentFieldSchema)
+	var fields *AgentFields
+	if values != nil {
+		fields = &AgentFields{
+			RoleType:   values["role_type"],
+			Rig:        values["rig"],
+			AgentState: values["agent_state"],
+			HookBead:   values["hook_bead"],
+			RoleBead:   values["role_bead"],
+		}
 	}
+	return fields, issues, fieldIssuesError(issues)
+}
 
-	return strings.Join(lines, "\n")
+// AttachmentFieldSchema declares the fields stored in a pinned bead's attachment.
+var AttachmentFieldSchema = &FieldSchema{
+	Name: "attachment",
+	Fields: []FieldDef{
+		{Key: "attached_molecule", Aliases: []string{"attached-molecule", "attachedmolecule"}, Validate: validateBeadID},
+		{Key: "attached_at", Aliases: []string{"attached-at", "attachedat"}, Validate: validateRFC3339},
+		{Key: "attached_args", Aliases: []string{"attached-args", "attachedargs"}},
+	},
 }
 
 // AttachmentFields holds the attachment info for pinned beads.
@@ -113,49 +103,15 @@ type AttachmentFields struct {
 // ParseAttachmentFields extracts attachment fields from an issue's description.
 // Fields are expected as "key: value" lines. Returns nil if no attachment fields found.
 func ParseAttachmentFields(issue *Issue) *AttachmentFields {
-	if issue == nil || issue.Description == "" {
+	values := ParseFields(issue, AttachmentFieldSchema)
+	if values == nil {
 		return nil
 	}
-
-	fields := &AttachmentFields{}
-	hasFields := false
-
-	for _, line := range strings.Split(issue.Description, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Look for "key: value" pattern
-		colonIdx := strings.Index(line, ":")
-		if colonIdx == -1 {
-			continue
-		}
-
-		key := strings.TrimSpace(line[:colonIdx])
-		value := strings.TrimSpace(line[colonIdx+1:])
-		if value == "" {
-			continue
-		}
-
-		// Map keys to fields (case-insensitive)
-		switch strings.ToLower(key) {
-		case "attached_molecule", "attached-molecule", "attachedmolecule":
-			fields.AttachedMolecule = value
-			hasFields = true
-		case "attached_at", "attached-at", "attachedat":
-			fields.AttachedAt = value
-			hasFields = true
-		case "attached_args", "attached-args", "attachedargs":
-			fields.AttachedArgs = value
-			hasFields = true
-		}
-	}
-
-	if !hasFields {
-		return nil
+	return &AttachmentFields{
+		AttachedMolecule: values["attached_molecule"],
+		AttachedAt:       values["attached_at"],
+		AttachedArgs:     values["attached_args"],
 	}
-	return fields
 }
 
 // FormatAttachmentFields formats AttachmentFields as a string suitable for an issue description.
@@ -164,85 +120,64 @@ func FormatAttachmentFields(fields *AttachmentFields) string {
 	if fields == nil {
 		return ""
 	}
+	return FormatFields(AttachmentFieldSchema, map[string]string{
+		"attached_molecule": fields.AttachedMolecule,
+		"attached_at":       fields.AttachedAt,
+		"attached_args":     fields.AttachedArgs,
+	})
+}
 
-	var lines []string
-
-	if fields.AttachedMolecule != "" {
-		lines = append(lines, "attached_molecule: "+fields.AttachedMolecule)
-	}
-	if fields.AttachedAt != "" {
-		lines = append(lines, "attached_at: "+fields.AttachedAt)
-	}
-	if fields.AttachedArgs != "" {
-		lines = append(lines, "attached_args: "+fields.AttachedArgs)
+// ParseAttachmentFieldsStrict is like ParseAttachmentFields but validates
+// attached_molecule against the bead-ID pattern and attached_at as an RFC
+// 3339 timestamp, returning one FieldIssue per invalid value. The returned
+// error is a summary of issues for callers that only need a pass/fail check.
+func ParseAttachmentFieldsStrict(issue *Issue) (*AttachmentFields, []FieldIssue, error) {
+	values, issues := ParseFieldsStrict(issue, AttachmentFieldSchema)
+	var fields *AttachmentFields
+	if values != nil {
+		fields = &AttachmentFields{
+			AttachedMolecule: values["attached_molecule"],
+			AttachedAt:       values["attached_at"],
+			AttachedArgs:     values["attached_args"],
+		}
 	}
-
-	return strings.Join(lines, "\n")
+	return fields, issues, fieldIssuesError(issues)
 }
 
-// SetAttachmentFields updates an issue's description with the given attachment fields.
-// Existing attachment field lines are replaced; other content is preserved.
-// Returns the new description string.
+// SetAttachmentFields updates an issue's description with the given
+// attachment fields, using DefaultFieldWritePolicy. Existing attachment
+// field lines are updated in place; other content, including unknown keys,
+// is preserved untouched. Returns the new description string.
 func SetAttachmentFields(issue *Issue, fields *AttachmentFields) string {
-	// Known attachment field keys (lowercase)
-	attachmentKeys := map[string]bool{
-		"attached_molecule": true,
-		"attached-molecule": true,
-		"attachedmolecule":  true,
-		"attached_at":       true,
-		"attached-at":       true,
-		"attachedat":        true,
-		"attached_args":     true,
-		"attached-args":     true,
-		"attachedargs":      true,
-	}
-
-	// Collect non-attachment lines from existing description
-	var otherLines []string
-	if issue != nil && issue.Description != "" {
-		for _, line := range strings.Split(issue.Description, "\n") {
-			trimmed := strings.TrimSpace(line)
-			if trimmed == "" {
-				// Preserve blank lines in content
-				otherLines = append(otherLines, line)
-				continue
-			}
-
-			// Check if this is an attachment field line
-			colonIdx := strings.Index(trimmed, ":")
-			if colonIdx == -1 {
-				otherLines = append(otherLines, line)
-				continue
-			}
+	return SetAttachmentFieldsWithPolicy(issue, fields, DefaultFieldWritePolicy)
+}
 
-			key := strings.ToLower(strings.TrimSpace(trimmed[:colonIdx]))
-			if !attachmentKeys[key] {
-				otherLines = append(otherLines, line)
-			}
-			// Skip attachment field lines - they'll be replaced
+// SetAttachmentFieldsWithPolicy is like SetAttachmentFields but lets the
+// caller control field placement and alias canonicalization via policy.
+func SetAttachmentFieldsWithPolicy(issue *Issue, fields *AttachmentFields, policy FieldWritePolicy) string {
+	values := map[string]string{}
+	if fields != nil {
+		values = map[string]string{
+			"attached_molecule": fields.AttachedMolecule,
+			"attached_at":       fields.AttachedAt,
+			"attached_args":     fields.AttachedArgs,
 		}
 	}
+	return SetFields(issue, AttachmentFieldSchema, values, policy)
+}
 
-	// Build new description: attachment fields first, then other content
-	formatted := FormatAttachmentFields(fields)
-
-	// Trim trailing blank lines from other content
-	for len(otherLines) > 0 && strings.TrimSpace(otherLines[len(otherLines)-1]) == "" {
-		otherLines = otherLines[:len(otherLines)-1]
-	}
-	// Trim leading blank lines from other content
-	for len(otherLines) > 0 && strings.TrimSpace(otherLines[0]) == "" {
-		otherLines = otherLines[1:]
-	}
-
-	if formatted == "" {
-		return strings.Join(otherLines, "\n")
-	}
-	if len(otherLines) == 0 {
-		return formatted
-	}
-
-	return formatted + "\n\n" + strings.Join(otherLines, "\n")
+// MRFieldSchema declares the fields stored in a merge-request issue's description.
+var MRFieldSchema = &FieldSchema{
+	Name: "mr",
+	Fields: []FieldDef{
+		{Key: "branch"},
+		{Key: "target"},
+		{Key: "source_issue", Aliases: []string{"source-issue", "sourceissue"}, Validate: validateBeadID},
+		{Key: "worker"},
+		{Key: "rig"},
+		{Key: "merge_commit", Aliases: []string{"merge-commit", "mergecommit"}, Validate: validateSHA},
+		{Key: "close_reason", Aliases: []string{"close-reason", "closereason"}, Validate: validateCloseReason},
+	},
 }
 
 // MRFields holds the structured fields for a merge-request issue.
@@ -261,61 +196,19 @@ type MRFields struct {
 // Fields are expected as "key: value" lines, with optional prose text mixed in.
 // Returns nil if no MR fields are found.
 func ParseMRFields(issue *Issue) *MRFields {
-	if issue == nil || issue.Description == "" {
+	values := ParseFields(issue, MRFieldSchema)
+	if values == nil {
 		return nil
 	}
-
-	fields := &MRFields{}
-	hasFields := false
-
-	for _, line := range strings.Split(issue.Description, "\n") {
-		line = strings.TrimSpace(line)
-		if line == "" {
-			continue
-		}
-
-		// Look for "key: value" pattern
-		colonIdx := strings.Index(line, ":")
-		if colonIdx == -1 {
-			continue
-		}
-
-		key := strings.TrimSpace(line[:colonIdx])
-		value := strings.TrimSpace(line[colonIdx+1:])
-		if value == "" {
-			continue
-		}
-
-		// Map keys to fields (case-insensitive)
-		switch strings.ToLower(key) {
-		case "branch":
-			fields.Branch = value
-			hasFields = true
-		case "target":
-			fields.Target = value
-			hasFields = true
-		case "source_issue", "source-issue", "sourceissue":
-			fields.SourceIssue = value
-			hasFields = true
-		case "worker":
-			fields.Worker = value
-			hasFields = true
-		case "rig":
-			fields.Rig = value
-			hasFields = true
-		case "merge_commit", "merge-commit", "mergecommit":
-			fields.MergeCommit = value
-			hasFields = true
-		case "close_reason", "close-reason", "closereason":
-			fields.CloseReason = value
-			hasFields = true
-		}
+	return &MRFields{
+		Branch:      values["branch"],
+		Target:      values["target"],
+		SourceIssue: values["source_issue"],
+		Worker:      values["worker"],
+		Rig:         values["rig"],
+		MergeCommit: values["merge_commit"],
+		CloseReason: values["close_reason"],
 	}
-
-	if !hasFields {
-		return nil
-	}
-	return fields
 }
 
 // FormatMRFields formats MRFields as a string suitable for an issue description.
@@ -324,103 +217,61 @@ func FormatMRFields(fields *MRFields) string {
 	if fields == nil {
 		return ""
 	}
+	return FormatFields(MRFieldSchema, map[string]string{
+		"branch":       fields.Branch,
+		"target":       fields.Target,
+		"source_issue": fields.SourceIssue,
+		"worker":       fields.Worker,
+		"rig":          fields.Rig,
+		"merge_commit": fields.MergeCommit,
+		"close_reason": fields.CloseReason,
+	})
+}
 
-	var lines []string
-
-	if fields.Branch != "" {
-		lines = append(lines, "branch: "+fields.Branch)
-	}
-	if fields.Target != "" {
-		lines = append(lines, "target: "+fields.Target)
-	}
-	if fields.SourceIssue != "" {
-		lines = append(lines, "source_issue: "+fields.SourceIssue)
-	}
-	if fields.Worker != "" {
-		lines = append(lines, "worker: "+fields.Worker)
-	}
-	if fields.Rig != "" {
-		lines = append(lines, "rig: "+fields.Rig)
-	}
-	if fields.MergeCommit != "" {
-		lines = append(lines, "merge_commit: "+fields.MergeCommit)
-	}
-	if fields.CloseReason != "" {
-		lines = append(lines, "close_reason: "+fields.CloseReason)
+// ParseMRFieldsStrict is like ParseMRFields but validates source_issue
+// against the bead-ID pattern, merge_commit as a 7-40 character hex SHA, and
+// close_reason against its known enum, returning one FieldIssue per invalid
+// value. The returned error is a summary of issues for callers that only
+// need a pass/fail check.
+func ParseMRFieldsStrict(issue *Issue) (*MRFields, []FieldIssue, error) {
+	values, issues := ParseFieldsStrict(issue, MRFieldSchema)
+	var fields *MRFields
+	if values != nil {
+		fields = &MRFields{
+			Branch:      values["branch"],
+			Target:      values["target"],
+			SourceIssue: values["source_issue"],
+			Worker:      values["worker"],
+			Rig:         values["rig"],
+			MergeCommit: values["merge_commit"],
+			CloseReason: values["close_reason"],
+		}
 	}
-
-	return strings.Join(lines, "\n")
+	return fields, issues, fieldIssuesError(issues)
 }
 
-// SetMRFields updates an issue's description with the given MR fields.
-// Existing MR field lines are replaced; other content is preserved.
+// SetMRFields updates an issue's description with the given MR fields,
+// using DefaultFieldWritePolicy. Existing MR field lines are updated in
+// place; other content, including unknown keys, is preserved untouched.
 // Returns the new description string.
 func SetMRFields(issue *Issue, fields *MRFields) string {
-	if issue == nil {
-		return FormatMRFields(fields)
-	}
-
-	// Known MR field keys (lowercase)
-	mrKeys := map[string]bool{
-		"branch":       true,
-		"target":       true,
-		"source_issue": true,
-		"source-issue": true,
-		"sourceissue":  true,
-		"worker":       true,
-		"rig":          true,
-		"merge_commit": true,
-		"merge-commit": true,
-		"mergecommit":  true,
-		"close_reason": true,
-		"close-reason": true,
-		"closereason":  true,
-	}
-
-	// Collect non-MR lines from existing description
-	var otherLines []string
-	if issue.Description != "" {
-		for _, line := range strings.Split(issue.Description, "\n") {
-			trimmed := strings.TrimSpace(line)
-			if trimmed == "" {
-				// Preserve blank lines in content
-				otherLines = append(otherLines, line)
-				continue
-			}
-
-			// Check if this is an MR field line
-			colonIdx := strings.Index(trimmed, ":")
-			if colonIdx == -1 {
-				otherLines = append(otherLines, line)
-				continue
-			}
+	return SetMRFieldsWithPolicy(issue, fields, DefaultFieldWritePolicy)
+}
 
-			key := strings.ToLower(strings.TrimSpace(trimmed[:colonIdx]))
-			if !mrKeys[key] {
-				otherLines = append(otherLines, line)
-			}
-			// Skip MR field lines - they'll be replaced
+// SetMRFieldsWithPolicy is like SetMRFields but lets the caller control
+// field placement and alias canonicalization via policy.
+func SetMRFieldsWithPolicy(issue *Issue, fields *MRFields, policy FieldWritePolicy) string {
+	values := map[string]string{}
+	if fields != nil {
+		values = map[string]string{
+			"branch":       fields.Branch,
+			"target":       fields.Target,
+			"source_issue": fields.SourceIssue,
+			"worker":       fields.Worker,
+			"rig":          fields.Rig,
+			"merge_commit": fields.MergeCommit,
+			"close_reason": fields.CloseReason,
 		}
 	}
-
-	// Build new description: MR fields first, then other content
-	formatted := FormatMRFields(fields)
-
-	// Trim trailing blank lines from other content
-	for len(otherLines) > 0 && strings.TrimSpace(otherLines[len(otherLines)-1]) == "" {
-		otherLines = otherLines[:len(otherLines)-1]
-	}
-	// Trim leading blank lines from other content
-	for len(otherLines) > 0 && strings.TrimSpace(otherLines[0]) == "" {
-		otherLines = otherLines[1:]
-	}
-
-	if formatted == "" {
-		return strings.Join(otherLines, "\n")
-	}
-	if len(otherLines) == 0 {
-		return formatted
-	}
-
-	return formatted + "\n\n" + strings.Join(otherLines, "\n")
+	return SetFields(issue, MRFieldSchema, values, policy)
 }
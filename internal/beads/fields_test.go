@@ -0,0 +1,176 @@
+package beads
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetMRFieldsPreservesProseAndUnknownKeys(t *testing.T) {
+	issue := &Issue{Description: "This MR fixes the flaky retry loop.\n\n" +
+		"branch: polecat/Nux/gt-xyz\n" +
+		"reviewer: ambrose\n" +
+		"target: main\n"}
+
+	got := SetMRFields(issue, &MRFields{Branch: "polecat/Nux/gt-xyz", Target: "integration/gt-epic"})
+
+	want := "This MR fixes the flaky retry loop.\n\n" +
+		"branch: polecat/Nux/gt-xyz\n" +
+		"reviewer: ambrose\n" +
+		"target: integration/gt-epic"
+	if got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestSetMRFieldsPreservesCRLF(t *testing.T) {
+	issue := &Issue{Description: "branch: polecat/x\r\ntarget: main\r\n"}
+
+	got := SetMRFields(issue, &MRFields{Branch: "polecat/x", Target: "integration/gt-epic"})
+
+	want := "branch: polecat/x\r\ntarget: integration/gt-epic"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSetMRFieldsSkipsFencedCodeBlocks(t *testing.T) {
+	issue := &Issue{Description: "branch: polecat/x\n\n" +
+		"Example config:\n\n" +
+		"```\n" +
+		"target: staging\n" +
+		"```\n"}
+
+	got := SetMRFields(issue, &MRFields{Branch: "polecat/x", Target: "main"})
+
+	want := "target: main\n\n" +
+		"branch: polecat/x\n\n" +
+		"Example config:\n\n" +
+		"```\n" +
+		"target: staging\n" +
+		"```"
+	if got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+
+	// Most importantly: the fenced "target: staging" line itself must
+	// survive untouched, never rewritten to "target: main".
+	if !strings.Contains(got, "target: staging") {
+		t.Errorf("fenced target: staging line was rewritten:\n%q", got)
+	}
+}
+
+func TestSetMRFieldsIdempotent(t *testing.T) {
+	issue := &Issue{Description: "Some rationale first.\n\nbranch: polecat/x\n"}
+	fields := &MRFields{Branch: "polecat/x", Target: "main", Worker: "ambrose"}
+
+	first := SetMRFields(issue, fields)
+	second := SetMRFields(&Issue{Description: first}, fields)
+
+	if first != second {
+		t.Errorf("not idempotent:\nfirst:\n%q\nsecond:\n%q", first, second)
+	}
+}
+
+func TestSetMRFieldsAnchorBottom(t *testing.T) {
+	issue := &Issue{Description: "Rationale paragraph."}
+
+	got := SetMRFields(issue, &MRFields{}) // no fields to add, body unchanged
+	if got != "Rationale paragraph." {
+		t.Errorf("got %q", got)
+	}
+
+	policy := FieldWritePolicy{Anchor: AnchorBottom}
+	got = SetMRFieldsWithPolicy(issue, &MRFields{Branch: "polecat/x"}, policy)
+	want := "Rationale paragraph.\n\nbranch: polecat/x"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSetMRFieldsAnchorMarker(t *testing.T) {
+	issue := &Issue{Description: "Rationale paragraph.\n\n<!-- beads:fields -->\n"}
+	policy := FieldWritePolicy{Anchor: AnchorMarker, Marker: "<!-- beads:fields -->"}
+
+	got := SetMRFieldsWithPolicy(issue, &MRFields{Branch: "polecat/x", Target: "main"}, policy)
+
+	want := "Rationale paragraph.\n\n<!-- beads:fields -->\nbranch: polecat/x\ntarget: main\n"
+	if got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func TestSetMRFieldsAnchorMarkerIdempotent(t *testing.T) {
+	issue := &Issue{Description: "Rationale paragraph.\n\n<!-- beads:fields -->\n"}
+	policy := FieldWritePolicy{Anchor: AnchorMarker, Marker: "<!-- beads:fields -->"}
+	fields := &MRFields{Branch: "polecat/x", Target: "main"}
+
+	first := SetMRFieldsWithPolicy(issue, fields, policy)
+	second := SetMRFieldsWithPolicy(&Issue{Description: first}, fields, policy)
+
+	if first != second {
+		t.Errorf("not idempotent:\nfirst:\n%q\nsecond:\n%q", first, second)
+	}
+}
+
+func TestSetMRFieldsAddsNewFieldInsideExistingFrontmatterBlock(t *testing.T) {
+	// SetFields wraps the fields of a description that had none yet in a
+	// "---" delimited block. Adding a brand-new field to one
+	// must not corrupt it into two separate field sections.
+	issue := &Issue{Description: "---\nbranch: polecat/x\n---\n\nSome rationale."}
+
+	got := SetMRFields(issue, &MRFields{Branch: "polecat/x", Target: "main"})
+
+	want := "---\nbranch: polecat/x\ntarget: main\n---\n\nSome rationale."
+	if got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+
+	// Idempotent: re-applying the same fields makes no further change.
+	second := SetMRFields(&Issue{Description: got}, &MRFields{Branch: "polecat/x", Target: "main"})
+	if second != got {
+		t.Errorf("not idempotent:\nfirst:\n%q\nsecond:\n%q", got, second)
+	}
+}
+
+func TestSetMRFieldsWrapsFrontmatterForBrandNewDescription(t *testing.T) {
+	// A description with no recognized fields at all yet must get a single
+	// "---" delimited frontmatter block, not loose key: value lines, per the
+	// original field-schema request.
+	issue := &Issue{Description: "Some rationale paragraph with no fields yet."}
+
+	got := SetMRFields(issue, &MRFields{Branch: "polecat/x", Target: "main"})
+
+	want := "---\nbranch: polecat/x\ntarget: main\n---\n\nSome rationale paragraph with no fields yet."
+	if got != want {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+
+	// Idempotent: re-applying the same fields makes no further change.
+	second := SetMRFields(&Issue{Description: got}, &MRFields{Branch: "polecat/x", Target: "main"})
+	if second != got {
+		t.Errorf("not idempotent:\nfirst:\n%q\nsecond:\n%q", got, second)
+	}
+}
+
+func TestSetAttachmentFieldsCanonicalizesAliasWhenRequested(t *testing.T) {
+	issue := &Issue{Description: "attached-molecule: gt-123\n"}
+
+	policy := FieldWritePolicy{Anchor: AnchorTop, CanonicalizeKeys: true}
+	got := SetAttachmentFieldsWithPolicy(issue, &AttachmentFields{AttachedMolecule: "gt-456"}, policy)
+
+	want := "attached_molecule: gt-456"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSetAttachmentFieldsClearsFieldWithEmptyValue(t *testing.T) {
+	issue := &Issue{Description: "attached_molecule: gt-123\nattached_at: 2026-07-25T00:00:00Z\n"}
+
+	got := SetAttachmentFields(issue, &AttachmentFields{AttachedAt: "2026-07-25T00:00:00Z"})
+
+	want := "attached_at: 2026-07-25T00:00:00Z"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,494 @@
+package beads
+
+import (
+	"fmt"
+	"strings"
+)
+
+// FieldDef describes a single key: value field within a FieldSchema.
+type FieldDef struct {
+	Key     string   // canonical key, e.g. "role_type"
+	Aliases []string // alternate spellings accepted on parse, e.g. "role-type", "roletype"
+
+	// Validate, if set, checks a non-empty value for this field and returns
+	// an error describing why it is invalid (e.g. not one of a known enum,
+	// not an RFC 3339 timestamp). It is only consulted by the Strict parse
+	// functions; the lenient Parse*Fields functions accept any value.
+	Validate func(value string) error
+}
+
+// FieldSchema declares the set of fields a bead kind (agent, attachment, mr, ...)
+// stores as key: value lines in its issue description, along with their
+// serialization order. ParseFields, FormatFields and SetFields are all driven
+// by a FieldSchema so each bead kind only has to declare its keys once.
+type FieldSchema struct {
+	Name   string // bead kind, e.g. "agent", "attachment", "mr"
+	Fields []FieldDef
+}
+
+// aliasMap returns a lowercase alias (and canonical key) -> canonical key lookup.
+func (s *FieldSchema) aliasMap() map[string]string {
+	m := make(map[string]string, len(s.Fields))
+	for _, f := range s.Fields {
+		m[strings.ToLower(f.Key)] = f.Key
+		for _, a := range f.Aliases {
+			m[strings.ToLower(a)] = f.Key
+		}
+	}
+	return m
+}
+
+// ParseFields extracts the schema's fields from an issue's description,
+// returning canonical key -> value. Unknown keys are ignored. Returns nil if
+// none of the schema's fields are present.
+func ParseFields(issue *Issue, schema *FieldSchema) map[string]string {
+	if issue == nil {
+		return nil
+	}
+	return ParseFieldsFromDescription(issue.Description, schema)
+}
+
+// ParseFieldsFromDescription is like ParseFields but operates on a raw
+// description string. It accepts both a "---" delimited YAML frontmatter
+// block at the top of the description and loose "key: value" lines scattered
+// through the text, so descriptions written before frontmatter support remain
+// readable.
+func ParseFieldsFromDescription(description string, schema *FieldSchema) map[string]string {
+	if description == "" {
+		return nil
+	}
+
+	aliases := schema.aliasMap()
+	values := make(map[string]string)
+
+	for _, m := range fieldLinesFromDescription(description) {
+		if m.value == "" || m.value == "null" {
+			continue
+		}
+		if canonical, known := aliases[strings.ToLower(m.key)]; known {
+			values[canonical] = m.value
+		}
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+	return values
+}
+
+// FieldIssue describes one problem found while strictly parsing a bead's
+// fields: an unknown enum value, a malformed timestamp, an invalid bead ID,
+// and so on.
+type FieldIssue struct {
+	Line   int    // 1-indexed line number within the description
+	Key    string // canonical field key
+	Value  string // the raw value that failed validation
+	Reason string
+}
+
+// ParseFieldsStrict is like ParseFields but additionally validates every
+// present field against its FieldDef.Validate (when set), returning one
+// FieldIssue per invalid value. Values are included in the returned map
+// whether or not they are valid, so callers can still use best-effort data
+// alongside the diagnostics.
+func ParseFieldsStrict(issue *Issue, schema *FieldSchema) (map[string]string, []FieldIssue) {
+	if issue == nil {
+		return nil, nil
+	}
+	return ParseFieldsFromDescriptionStrict(issue.Description, schema)
+}
+
+// ParseFieldsFromDescriptionStrict is like ParseFieldsStrict but operates on
+// a raw description string.
+func ParseFieldsFromDescriptionStrict(description string, schema *FieldSchema) (map[string]string, []FieldIssue) {
+	if description == "" {
+		return nil, nil
+	}
+
+	aliases := schema.aliasMap()
+	validators := make(map[string]func(string) error, len(schema.Fields))
+	for _, f := range schema.Fields {
+		if f.Validate != nil {
+			validators[f.Key] = f.Validate
+		}
+	}
+
+	values := make(map[string]string)
+	var issues []FieldIssue
+	for _, m := range fieldLinesFromDescription(description) {
+		if m.value == "" || m.value == "null" {
+			continue
+		}
+		canonical, known := aliases[strings.ToLower(m.key)]
+		if !known {
+			continue
+		}
+		values[canonical] = m.value
+		if validate, ok := validators[canonical]; ok {
+			if err := validate(m.value); err != nil {
+				issues = append(issues, FieldIssue{Line: m.num, Key: canonical, Value: m.value, Reason: err.Error()})
+			}
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, issues
+	}
+	return values, issues
+}
+
+// fieldIssuesError summarizes issues as a single error, or nil if there are
+// none, so callers that only want a pass/fail check don't have to inspect
+// the slice themselves.
+func fieldIssuesError(issues []FieldIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	if len(issues) == 1 {
+		return fmt.Errorf("field issue: %s: %s", issues[0].Key, issues[0].Reason)
+	}
+	return fmt.Errorf("%d field issues, first: %s: %s", len(issues), issues[0].Key, issues[0].Reason)
+}
+
+// FormatFields renders values as "key: value" lines in schema order. Only
+// keys declared by the schema and present with a non-empty value are
+// emitted.
+func FormatFields(schema *FieldSchema, values map[string]string) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	var lines []string
+	for _, f := range schema.Fields {
+		if v, ok := values[f.Key]; ok && v != "" {
+			lines = append(lines, f.Key+": "+v)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// FieldAnchor controls where SetFields places newly-added fields that had no
+// existing line to update in place.
+type FieldAnchor int
+
+const (
+	// AnchorTop places new fields at the very top of the description.
+	AnchorTop FieldAnchor = iota
+	// AnchorBottom places new fields at the very end of the description.
+	AnchorBottom
+	// AnchorMarker places new fields immediately after a line matching
+	// FieldWritePolicy.Marker, falling back to AnchorBottom if the marker
+	// isn't present in the description.
+	AnchorMarker
+)
+
+// FieldWritePolicy controls how SetFields places new fields and whether it
+// canonicalizes alias spellings of fields it updates in place.
+type FieldWritePolicy struct {
+	Anchor FieldAnchor
+	Marker string // the line SetFields looks for when Anchor is AnchorMarker, e.g. "<!-- beads:fields -->"
+
+	// CanonicalizeKeys rewrites an existing field line's key to the
+	// schema's canonical spelling when true. When false (the default), an
+	// updated line keeps whatever key spelling (alias, casing) it already had.
+	CanonicalizeKeys bool
+}
+
+// DefaultFieldWritePolicy is used by SetMRFields, SetAttachmentFields and
+// other callers that don't need to customize placement: new fields go at
+// the top, and existing alias spellings are left as they were written.
+var DefaultFieldWritePolicy = FieldWritePolicy{Anchor: AnchorTop}
+
+// SetFields updates an issue's description with the given field values.
+// Rather than collecting all known fields into one block on every write, it
+// walks the existing description line by line: a line whose key matches a
+// schema field has its value replaced in place (keeping indentation,
+// surrounding blank lines and the line's original key spelling unless
+// policy.CanonicalizeKeys is set); lines inside fenced code blocks and
+// lines for unrecognized keys are left untouched. A field present in the
+// schema with an empty or missing value has its existing line removed.
+//
+// Fields with no existing line to update are placed as follows:
+//   - if the description already carries a "---" delimited frontmatter
+//     block, new fields are inserted inside it, regardless of
+//     policy.Anchor, so the description is never split into two field
+//     sections;
+//   - otherwise, if the description has no fields at all yet, the new
+//     fields are written as a single "---" delimited frontmatter block
+//     followed by the free-text body (only for the default AnchorTop);
+//   - otherwise — a description with some existing loose, non-frontmatter
+//     fields plus new ones to add — the new fields are placed at
+//     policy.Anchor as plain key: value lines, matching the existing
+//     fields' own loose format instead of introducing a second, wrapped
+//     section.
+//
+// Returns the new description string, preserving the original line ending
+// style (LF or CRLF).
+func SetFields(issue *Issue, schema *FieldSchema, values map[string]string, policy FieldWritePolicy) string {
+	var description string
+	if issue != nil {
+		description = issue.Description
+	}
+
+	nl := "\n"
+	if strings.Contains(description, "\r\n") {
+		nl = "\r\n"
+	}
+
+	aliases := schema.aliasMap()
+	pending := make(map[string]string, len(values))
+	for k, v := range values {
+		if v != "" {
+			pending[k] = v
+		}
+	}
+
+	var out []string
+	inFence := false
+	fenceMarker := ""
+	hadExistingFields := false
+
+	for _, raw := range strings.Split(description, "\n") {
+		line := strings.TrimSuffix(raw, "\r")
+		trimmed := strings.TrimSpace(line)
+
+		if fence := fenceDelimiter(trimmed); fence != "" {
+			if inFence && fence == fenceMarker {
+				inFence = false
+				fenceMarker = ""
+			} else if !inFence {
+				inFence = true
+				fenceMarker = fence
+			}
+			out = append(out, line)
+			continue
+		}
+		if inFence {
+			out = append(out, line)
+			continue
+		}
+
+		key, _, ok := splitFieldLine(line)
+		if !ok {
+			out = append(out, line)
+			continue
+		}
+		canonical, known := aliases[strings.ToLower(key)]
+		if !known {
+			out = append(out, line)
+			continue
+		}
+		hadExistingFields = true
+
+		newValue, wanted := pending[canonical]
+		if !wanted {
+			// A known field with no new value: drop the line.
+			continue
+		}
+
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		outKey := key
+		if policy.CanonicalizeKeys {
+			outKey = canonical
+		}
+		out = append(out, indent+outKey+": "+newValue)
+		delete(pending, canonical)
+	}
+
+	var newLines []string
+	for _, f := range schema.Fields {
+		if v, ok := pending[f.Key]; ok {
+			newLines = append(newLines, f.Key+": "+v)
+		}
+	}
+
+	switch {
+	case len(newLines) == 0:
+		out = insertNewFieldLines(out, newLines, policy)
+	default:
+		// Descriptions already carrying a "---" delimited frontmatter block
+		// must keep new fields inside that block; otherwise the configured
+		// anchor would add a loose key: value line above or below it,
+		// leaving two conflicting field sections in the same description.
+		if frontOut, ok := insertIntoFrontmatter(out, newLines); ok {
+			out = frontOut
+		} else if policy.Anchor == AnchorTop && !hadExistingFields {
+			// A brand-new write with no fields at all yet: produce a
+			// single frontmatter block followed by the free-text body,
+			// per the original field-schema request, rather than loose
+			// key: value lines.
+			out = insertNewFieldLines(out, wrapFrontmatter(newLines), policy)
+		} else {
+			out = insertNewFieldLines(out, newLines, policy)
+		}
+	}
+	return strings.Join(out, nl)
+}
+
+// wrapFrontmatter wraps lines in a "---" delimited frontmatter block.
+func wrapFrontmatter(lines []string) []string {
+	out := make([]string, 0, len(lines)+2)
+	out = append(out, "---")
+	out = append(out, lines...)
+	out = append(out, "---")
+	return out
+}
+
+// insertIntoFrontmatter inserts newLines just before the closing "---" of a
+// frontmatter block that lines begins with. ok is false (and lines is
+// returned unmodified) if newLines is empty or lines doesn't open with a
+// "---" delimited block, in which case the caller should fall back to the
+// configured FieldAnchor instead.
+func insertIntoFrontmatter(lines []string, newLines []string) ([]string, bool) {
+	if len(newLines) == 0 || len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return lines, false
+	}
+
+	for i := 1; i < len(lines); i++ {
+		if strings.TrimSpace(lines[i]) == "---" {
+			out := make([]string, 0, len(lines)+len(newLines))
+			out = append(out, lines[:i]...)
+			out = append(out, newLines...)
+			out = append(out, lines[i:]...)
+			return out, true
+		}
+	}
+
+	return lines, false
+}
+
+// insertNewFieldLines places newLines within lines according to policy. If
+// the marker anchor's marker line is found, lines is returned with newLines
+// spliced in right after it and is otherwise left byte-for-byte untouched
+// (no blank-line trimming) — this must hold whether or not newLines is
+// empty, or a second, no-op call would trim content the first call left
+// alone, breaking idempotency. Only the top/bottom (or marker-not-found)
+// paths trim leading/trailing blank lines from lines first.
+func insertNewFieldLines(lines []string, newLines []string, policy FieldWritePolicy) []string {
+	if policy.Anchor == AnchorMarker && policy.Marker != "" {
+		for i, l := range lines {
+			if strings.TrimSpace(l) == policy.Marker {
+				if len(newLines) == 0 {
+					return lines
+				}
+				out := make([]string, 0, len(lines)+len(newLines))
+				out = append(out, lines[:i+1]...)
+				out = append(out, newLines...)
+				out = append(out, lines[i+1:]...)
+				return out
+			}
+		}
+		// Marker not present: fall back to the bottom.
+	}
+
+	if len(newLines) == 0 {
+		return trimBlankLines(lines)
+	}
+
+	lines = trimBlankLines(lines)
+	if len(lines) == 0 {
+		return newLines
+	}
+
+	switch policy.Anchor {
+	case AnchorTop:
+		out := make([]string, 0, len(newLines)+1+len(lines))
+		out = append(out, newLines...)
+		out = append(out, "")
+		out = append(out, lines...)
+		return out
+	default: // AnchorBottom, or AnchorMarker with a missing marker
+		out := make([]string, 0, len(lines)+1+len(newLines))
+		out = append(out, lines...)
+		out = append(out, "")
+		out = append(out, newLines...)
+		return out
+	}
+}
+
+// fenceDelimiter reports the fenced-code-block marker ("```" or "~~~") a
+// trimmed line opens or closes with, or "" if it is not a fence line.
+func fenceDelimiter(trimmed string) string {
+	for _, marker := range []string{"```", "~~~"} {
+		if strings.HasPrefix(trimmed, marker) {
+			return marker
+		}
+	}
+	return ""
+}
+
+// fieldLineMatch is a "key: value" line found in a description, tagged with
+// its 1-indexed line number in the original text.
+type fieldLineMatch struct {
+	num   int
+	key   string
+	value string
+}
+
+// fieldLinesFromDescription returns every "key: value" line in description,
+// whether inside a "---" delimited frontmatter block or scattered as loose
+// lines in the body, so older descriptions written before frontmatter
+// support remain parseable and a frontmatter block doesn't shadow fields a
+// human or another tool appended below it. Lines inside fenced code blocks
+// are skipped so a documentation example isn't mistaken for a real field,
+// mirroring the fence-awareness SetFields already applies on write.
+func fieldLinesFromDescription(description string) []fieldLineMatch {
+	if description == "" {
+		return nil
+	}
+
+	var matches []fieldLineMatch
+	inFence := false
+	fenceMarker := ""
+	for i, line := range strings.Split(description, "\n") {
+		trimmed := strings.TrimSpace(line)
+
+		if fence := fenceDelimiter(trimmed); fence != "" {
+			if inFence && fence == fenceMarker {
+				inFence = false
+				fenceMarker = ""
+			} else if !inFence {
+				inFence = true
+				fenceMarker = fence
+			}
+			continue
+		}
+		if inFence {
+			continue
+		}
+
+		key, value, ok := splitFieldLine(line)
+		if !ok {
+			continue
+		}
+		matches = append(matches, fieldLineMatch{num: i + 1, key: key, value: value})
+	}
+	return matches
+}
+
+// splitFieldLine splits a trimmed "key: value" line into its key and value.
+// ok is false if line is not in that form.
+func splitFieldLine(line string) (key, value string, ok bool) {
+	trimmed := strings.TrimSpace(line)
+	if trimmed == "" {
+		return "", "", false
+	}
+	colonIdx := strings.Index(trimmed, ":")
+	if colonIdx == -1 {
+		return "", "", false
+	}
+	key = strings.TrimSpace(trimmed[:colonIdx])
+	value = strings.TrimSpace(trimmed[colonIdx+1:])
+	return key, value, true
+}
+
+// trimBlankLines trims leading and trailing blank lines from lines.
+func trimBlankLines(lines []string) []string {
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	return lines
+}
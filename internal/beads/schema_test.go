@@ -0,0 +1,100 @@
+package beads
+
+import "testing"
+
+func TestParseFieldsFromDescriptionLooseLines(t *testing.T) {
+	values := ParseFieldsFromDescription("branch: polecat/x\nreviewer: ambrose\ntarget: main\n", MRFieldSchema)
+
+	if values["branch"] != "polecat/x" || values["target"] != "main" {
+		t.Fatalf("got %+v", values)
+	}
+	if _, ok := values["reviewer"]; ok {
+		t.Errorf("unknown key %q should not be in the parsed fields", "reviewer")
+	}
+}
+
+func TestParseFieldsFromDescriptionAliases(t *testing.T) {
+	values := ParseFieldsFromDescription("role-type: deacon\nagentstate: idle\n", AgentFieldSchema)
+
+	if values["role_type"] != "deacon" {
+		t.Errorf("alias role-type did not resolve to canonical role_type: %+v", values)
+	}
+	if values["agent_state"] != "idle" {
+		t.Errorf("alias agentstate did not resolve to canonical agent_state: %+v", values)
+	}
+}
+
+func TestParseFieldsFromDescriptionFrontmatterBlock(t *testing.T) {
+	description := "---\nbranch: polecat/x\ntarget: main\n---\n\nSome prose about the change."
+
+	values := ParseFieldsFromDescription(description, MRFieldSchema)
+	if values["branch"] != "polecat/x" || values["target"] != "main" {
+		t.Fatalf("got %+v", values)
+	}
+}
+
+func TestParseFieldsFromDescriptionFrontmatterAndBody(t *testing.T) {
+	description := "---\nbranch: polecat/x\n---\n\ntarget: main\n"
+
+	values := ParseFieldsFromDescription(description, MRFieldSchema)
+	if values["branch"] != "polecat/x" || values["target"] != "main" {
+		t.Fatalf("loose body fields after a frontmatter block should still parse, got %+v", values)
+	}
+}
+
+func TestParseFieldsFromDescriptionSkipsFencedCodeBlock(t *testing.T) {
+	description := "role_type: mayor\n\n```\nrole_type: sheriff\n```\n"
+
+	values := ParseFieldsFromDescription(description, AgentFieldSchema)
+	if values["role_type"] != "mayor" {
+		t.Fatalf("fenced example value should not shadow the real field, got %+v", values)
+	}
+
+	_, issues := ParseFieldsFromDescriptionStrict(description, AgentFieldSchema)
+	for _, issue := range issues {
+		if issue.Key == "role_type" {
+			t.Errorf("fenced code block line should not produce a FieldIssue, got %+v", issue)
+		}
+	}
+}
+
+func TestParseFieldsFromDescriptionNullAndEmptyIgnored(t *testing.T) {
+	values := ParseFieldsFromDescription("branch: \ntarget: null\nworker: ambrose\n", MRFieldSchema)
+
+	if _, ok := values["branch"]; ok {
+		t.Errorf("empty value should not be parsed, got %+v", values)
+	}
+	if _, ok := values["target"]; ok {
+		t.Errorf("\"null\" value should not be parsed, got %+v", values)
+	}
+	if values["worker"] != "ambrose" {
+		t.Errorf("got %+v", values)
+	}
+}
+
+func TestFormatFieldsOrderAndOmitsEmpty(t *testing.T) {
+	got := FormatFields(MRFieldSchema, map[string]string{
+		"target": "main",
+		"branch": "polecat/x",
+		"worker": "",
+	})
+
+	want := "branch: polecat/x\ntarget: main"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSetFieldsGenericRoundTrip(t *testing.T) {
+	issue := &Issue{Description: "Prose up top.\n\nbranch: polecat/x\n"}
+
+	updated := SetFields(issue, MRFieldSchema, map[string]string{
+		"branch": "polecat/x",
+		"target": "main",
+	}, DefaultFieldWritePolicy)
+
+	values := ParseFieldsFromDescription(updated, MRFieldSchema)
+	if values["branch"] != "polecat/x" || values["target"] != "main" {
+		t.Fatalf("round-trip mismatch, description:\n%q\nvalues: %+v", updated, values)
+	}
+}